@@ -0,0 +1,54 @@
+/*
+Copyright 2021 Shiwen Cheng
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package complete shares the <branch|commit> shell-completion behavior
+// used by every git-mergex subcommand that takes one, so they all offer
+// the same remote branch choices and ActiveHelp hints.
+package complete
+
+import (
+	"fmt"
+
+	"github.com/chengshiwen/git-mergex/pkg/commands"
+	"github.com/spf13/cobra"
+)
+
+// Branch completes a <branch|commit> argument with remote branch names.
+// tip is an extra ActiveHelp hint specific to the calling subcommand,
+// appended alongside the branch git-mergex would operate on.
+func Branch(c *cobra.Command, mergex *commands.Mergex, args []string, tip string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	choices, err := mergex.Branches.Remote(mergex.Config.Remote)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	if cobra.GetActiveHelpConfig(c) == "0" {
+		return choices, cobra.ShellCompDirectiveNoFileComp
+	}
+	if len(choices) == 0 {
+		choices = cobra.AppendActiveHelp(choices, "No remote branches found; did you run 'git fetch'?")
+		return choices, cobra.ShellCompDirectiveNoFileComp
+	}
+	if tip != "" {
+		choices = cobra.AppendActiveHelp(choices, tip)
+	}
+	if branch, err := mergex.Status.HeadBranch(); err == nil {
+		choices = cobra.AppendActiveHelp(choices, fmt.Sprintf("You are on branch %s", branch))
+	}
+	return choices, cobra.ShellCompDirectiveNoFileComp
+}
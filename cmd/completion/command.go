@@ -44,6 +44,12 @@ PowerShell:
   # To load completions for every new session, run:
   PS> git-mergex completion powershell > git-mergex.ps1
   # and source this file from your PowerShell profile.
+
+Active Help:
+
+  Completion also surfaces inline hints (Cobra ActiveHelp) alongside the
+  candidate list, e.g. tips about --dry-run or which branch you are on.
+  Set GIT_MERGEX_ACTIVE_HELP=0 to turn these hints off.
 `
 
 func NewCommand() *cobra.Command {
@@ -56,7 +62,11 @@ func NewCommand() *cobra.Command {
 			if len(args) > 0 {
 				return nil, cobra.ShellCompDirectiveNoFileComp
 			}
-			return []string{"bash", "zsh", "fish", "powershell"}, cobra.ShellCompDirectiveNoFileComp
+			comps := []string{"bash", "zsh", "fish", "powershell"}
+			if cobra.GetActiveHelpConfig(cmd) != "0" {
+				comps = cobra.AppendActiveHelp(comps, "Pick the shell you want a completion script generated for")
+			}
+			return comps, cobra.ShellCompDirectiveNoFileComp
 		},
 		Args: cobra.ExactValidArgs(1),
 		Run: func(c *cobra.Command, args []string) {
@@ -0,0 +1,42 @@
+/*
+Copyright 2021 Shiwen Cheng
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package continuecmd implements `git-mergex continue`. It is named
+// continuecmd, not continue, because continue is a Go keyword.
+package continuecmd
+
+import (
+	"os"
+
+	"github.com/chengshiwen/git-mergex/pkg/commands"
+	"github.com/spf13/cobra"
+)
+
+// NewCommand builds `git-mergex continue`.
+func NewCommand(mergex *commands.Mergex) *cobra.Command {
+	return &cobra.Command{
+		Use:   "continue",
+		Short: "Continue merging after a git merge stops due to conflicts",
+		Args:  cobra.NoArgs,
+		RunE: func(c *cobra.Command, args []string) error {
+			branch, err := mergex.Status.HeadBranch()
+			if err != nil {
+				return err
+			}
+			return mergex.Continue(branch, os.Stdin, os.Stdout, os.Stderr)
+		},
+	}
+}
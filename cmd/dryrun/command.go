@@ -0,0 +1,47 @@
+/*
+Copyright 2021 Shiwen Cheng
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package dryrun implements `git-mergex dry-run <branch>`.
+package dryrun
+
+import (
+	"fmt"
+
+	"github.com/chengshiwen/git-mergex/cmd/complete"
+	"github.com/chengshiwen/git-mergex/pkg/commands"
+	"github.com/spf13/cobra"
+)
+
+// NewCommand builds `git-mergex dry-run <branch>`.
+func NewCommand(mergex *commands.Mergex) *cobra.Command {
+	return &cobra.Command{
+		Use:   "dry-run <branch|commit>",
+		Short: "Simulate merging a branch into the current branch",
+		Args:  cobra.ExactArgs(1),
+		ValidArgsFunction: func(c *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return complete.Branch(c, mergex, args, "Tip: dry-run never commits or touches your checkout")
+		},
+		RunE: func(c *cobra.Command, args []string) error {
+			branch, err := mergex.Status.HeadBranch()
+			if err != nil {
+				return err
+			}
+			out, err := mergex.DryRun(branch, mergex.Config.Remote, args[0])
+			fmt.Print(out)
+			return err
+		},
+	}
+}
@@ -0,0 +1,35 @@
+/*
+Copyright 2021 Shiwen Cheng
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package remove implements `git-mergex remove`.
+package remove
+
+import (
+	"github.com/chengshiwen/git-mergex/pkg/commands"
+	"github.com/spf13/cobra"
+)
+
+// NewCommand builds `git-mergex remove`.
+func NewCommand(mergex *commands.Mergex) *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove",
+		Short: "Remove all temporary mergex branches",
+		Args:  cobra.NoArgs,
+		RunE: func(c *cobra.Command, args []string) error {
+			return mergex.Remove()
+		},
+	}
+}
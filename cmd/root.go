@@ -23,7 +23,17 @@ import (
 	"runtime"
 	"strings"
 
+	"github.com/chengshiwen/git-mergex/cmd/abort"
 	"github.com/chengshiwen/git-mergex/cmd/completion"
+	continuecmd "github.com/chengshiwen/git-mergex/cmd/continue"
+	"github.com/chengshiwen/git-mergex/cmd/dryrun"
+	"github.com/chengshiwen/git-mergex/cmd/merge"
+	"github.com/chengshiwen/git-mergex/cmd/remove"
+	"github.com/chengshiwen/git-mergex/cmd/status"
+	"github.com/chengshiwen/git-mergex/cmd/worktree"
+	"github.com/chengshiwen/git-mergex/pkg/commands"
+	"github.com/chengshiwen/git-mergex/pkg/config"
+	"github.com/chengshiwen/git-mergex/pkg/oscommands"
 	"github.com/spf13/cobra"
 )
 
@@ -33,13 +43,13 @@ var (
 	BuildTime = "unknown"
 )
 
-const (
-	remote = "origin"
-	mergex = "_mergex"
-)
-
+// command is kept around for its deprecated `git-mergex --abort|--continue|
+// --remove|--dry-run [branch]` invocation, which now just dispatches to the
+// equivalent subcommand. New usage should reach for `git-mergex merge`,
+// `abort`, `continue`, `remove` and `dry-run` directly.
 type command struct {
 	cobraCmd *cobra.Command
+	mergex   *commands.Mergex
 	dryRun   bool
 	abort    bool
 	cont     bool
@@ -54,7 +64,18 @@ func Execute() {
 }
 
 func NewCommand() *cobra.Command {
-	cmd := &command{}
+	runner := oscommands.NewOSRunner()
+	cfg, err := config.Load(runner)
+	if err != nil {
+		cfg = &config.Config{
+			Remote:               config.DefaultRemote,
+			MergexPrefix:         config.DefaultMergexPrefix,
+			ForbiddenBranches:    config.DefaultForbiddenBranches,
+			MergeMessageTemplate: config.DefaultMergeMessageTemplate,
+			NoFF:                 true,
+		}
+	}
+	cmd := &command{mergex: commands.NewMergex(runner, cfg)}
 	cmd.cobraCmd = &cobra.Command{
 		Use:           "git-mergex <branch|commit>",
 		Short:         "git merge extension for aoneflow",
@@ -63,7 +84,7 @@ func NewCommand() *cobra.Command {
 		SilenceErrors: true,
 		Version:       version(),
 		ValidArgsFunction: func(c *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-			return cmd.comp(args, toComplete)
+			return cmd.comp(c, args, toComplete)
 		},
 		RunE: func(c *cobra.Command, args []string) error {
 			return cmd.runE(args)
@@ -71,37 +92,53 @@ func NewCommand() *cobra.Command {
 	}
 	cmd.cobraCmd.SetVersionTemplate(`{{.Version}}`)
 	pflags := cmd.cobraCmd.Flags()
-	pflags.BoolVarP(&cmd.dryRun, "dry-run", "d", false, "simulate to merge two development histories together")
-	pflags.BoolVarP(&cmd.abort, "abort", "a", false, "abort the current conflict resolution process")
-	pflags.BoolVarP(&cmd.cont, "continue", "c", false, "continue to merge after a git merge stops due to conflicts")
-	pflags.BoolVarP(&cmd.remove, "remove", "r", false, "remove all temporary mergex branches")
+	pflags.BoolVarP(&cmd.dryRun, "dry-run", "d", false, "deprecated, use the dry-run subcommand instead")
+	pflags.BoolVarP(&cmd.abort, "abort", "a", false, "deprecated, use the abort subcommand instead")
+	pflags.BoolVarP(&cmd.cont, "continue", "c", false, "deprecated, use the continue subcommand instead")
+	pflags.BoolVarP(&cmd.remove, "remove", "r", false, "deprecated, use the remove subcommand instead")
+	_ = pflags.MarkHidden("dry-run")
+	_ = pflags.MarkHidden("abort")
+	_ = pflags.MarkHidden("continue")
+	_ = pflags.MarkHidden("remove")
+	cmd.cobraCmd.AddCommand(merge.NewCommand(cmd.mergex))
+	cmd.cobraCmd.AddCommand(dryrun.NewCommand(cmd.mergex))
+	cmd.cobraCmd.AddCommand(abort.NewCommand(cmd.mergex))
+	cmd.cobraCmd.AddCommand(continuecmd.NewCommand(cmd.mergex))
+	cmd.cobraCmd.AddCommand(remove.NewCommand(cmd.mergex))
+	cmd.cobraCmd.AddCommand(status.NewCommand(cmd.mergex))
 	cmd.cobraCmd.AddCommand(completion.NewCommand())
+	cmd.cobraCmd.AddCommand(worktree.NewCommand())
 	return cmd.cobraCmd
 }
 
-func (cmd *command) comp(args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	if cmd.abort || cmd.cont || cmd.remove || len(args) > 0 {
+func (cmd *command) comp(c *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	activeHelpOn := cobra.GetActiveHelpConfig(c) != "0"
+
+	if cmd.abort || cmd.cont || cmd.remove {
+		var comps []string
+		if activeHelpOn {
+			comps = cobra.AppendActiveHelp(comps, "No branch argument accepted with this flag")
+		}
+		return comps, cobra.ShellCompDirectiveNoFileComp
+	}
+	if len(args) > 0 {
 		return nil, cobra.ShellCompDirectiveNoFileComp
 	}
-	branchCmd := exec.Command("git", "branch", "-r")
-	out, err := branchCmd.Output()
+
+	choices, err := cmd.mergex.Branches.Remote(cmd.mergex.Config.Remote)
 	if err != nil {
 		return nil, cobra.ShellCompDirectiveNoFileComp
 	}
-	branchSet := make(map[string]bool)
-	for _, item := range strings.Split(string(out), "\n") {
-		branch := strings.TrimSpace(item)
-		if len(branch) > 0 {
-			if strings.HasPrefix(branch, remoteBranch("HEAD")) {
-				continue
-			}
-			branch = strings.TrimPrefix(branch, remoteBranch(""))
-			branchSet[branch] = true
-		}
+	if !activeHelpOn {
+		return choices, cobra.ShellCompDirectiveNoFileComp
+	}
+	if len(choices) == 0 {
+		choices = cobra.AppendActiveHelp(choices, "No remote branches found; did you run 'git fetch'?")
+		return choices, cobra.ShellCompDirectiveNoFileComp
 	}
-	var choices []string
-	for branch := range branchSet {
-		choices = append(choices, branch)
+	choices = cobra.AppendActiveHelp(choices, "Tip: run with -d/--dry-run to preview the merge")
+	if branch, err := cmd.mergex.Status.HeadBranch(); err == nil {
+		choices = cobra.AppendActiveHelp(choices, fmt.Sprintf("You are on branch %s; merging will create %s/%s", branch, cmd.mergex.Config.MergexPrefix, branch))
 	}
 	return choices, cobra.ShellCompDirectiveNoFileComp
 }
@@ -114,156 +151,31 @@ func (cmd *command) runE(args []string) (err error) {
 	if err != nil {
 		return
 	}
-
-	branch, err := headBranch()
-	if err != nil {
-		return err
+	if cmd.abort || cmd.cont || cmd.remove || cmd.dryRun {
+		fmt.Fprintln(os.Stderr, "warning: flag-based invocation is deprecated, use the merge/abort/continue/remove/dry-run subcommands instead")
 	}
 
-	// --abort
-	if cmd.abort {
-		abortMerge()
-		resetCmd := exec.Command("git", "reset", "--hard", mergexBranch(branch))
-		err = resetCmd.Run()
-		if err != nil {
-			return commandError(resetCmd, err)
-		}
-		deleteBranch(mergexBranch(branch))
-		return nil
-	}
-
-	// --continue
-	if cmd.cont {
-		mergeCmd := exec.Command("git", "merge", "--continue")
-		mergeCmd.Stdin = os.Stdin
-		mergeCmd.Stdout = os.Stdout
-		_ = mergeCmd.Run()
-		deleteBranch(mergexBranch(branch))
-		return nil
-	}
-
-	// --remove
-	if cmd.remove {
-		branchCmd := exec.Command("git", "branch")
-		out, err := branchCmd.Output()
-		if err != nil {
-			return commandError(branchCmd, err)
-		}
-		branches := make([]string, 0)
-		for _, item := range strings.Split(string(out), "\n") {
-			_branch := strings.TrimSpace(item)
-			if len(_branch) > 0 {
-				if strings.HasPrefix(_branch, mergex) {
-					branches = append(branches, _branch)
-				}
-			}
-		}
-		if len(branches) > 0 {
-			rmCmd := &exec.Cmd{
-				Path: "git",
-				Args: append([]string{"git", "branch", "-D"}, branches...),
-			}
-			if lp, err := exec.LookPath("git"); err == nil {
-				rmCmd.Path = lp
-			}
-			rmCmd.Stdin = os.Stdin
-			rmCmd.Stdout = os.Stdout
-			_ = rmCmd.Run()
-		}
-		return nil
-	}
-
-	// fetch
-	fetchCmd := exec.Command("git", "fetch", "-f", remote, args[0])
-	out, err := fetchCmd.CombinedOutput()
+	branch, err := cmd.mergex.Status.HeadBranch()
 	if err != nil {
-		fmt.Print(string(out))
-		if strings.Contains(strings.ToLower(string(out)), "couldn't find remote ref") && strings.HasPrefix(args[0], remote) {
-			fmt.Printf("it seems that the branch '%s' should not start with '%s'\n", args[0], remote)
-		}
-		return commandError(fetchCmd, err)
-	}
-
-	// --dry-run
-	if cmd.dryRun {
-		mergeCmd := exec.Command("git", "merge", "--no-ff", "--no-commit", remoteBranch(args[0]))
-		out, _ = mergeCmd.CombinedOutput()
-		fmt.Print(strings.ReplaceAll(string(out), "; stopped before committing as requested", ""))
-		abortMerge()
-		return nil
-	}
-
-	// status
-	statusCmd := exec.Command("git", "status", "--porcelain", "-uno")
-	out, _ = statusCmd.Output()
-	outs := strings.TrimSpace(string(out))
-	if len(outs) > 0 {
-		return fmt.Errorf("Changes not committed before merge:\n%s", outs)
-	}
-
-	// merge
-	branchCmd := exec.Command("git", "branch", "-f", mergexBranch(branch))
-	err = branchCmd.Run()
-	if err != nil {
-		return commandError(branchCmd, err)
-	}
-	resetCmd := exec.Command("git", "reset", "--hard", remoteBranch(args[0]))
-	err = resetCmd.Run()
-	if err != nil {
-		return commandError(resetCmd, err)
-	}
-	mergeCmd := exec.Command("git", "merge", "--no-ff", "-m", fmt.Sprintf("Merge branch '%s' into %s", branch, args[0]), mergexBranch(branch))
-	out, err = mergeCmd.CombinedOutput()
-	if err == nil {
-		deleteBranch(mergexBranch(branch))
-	}
-	outs = string(out)
-	if strings.Contains(outs, "up to date") {
-		fmt.Printf("Fast-forward to %s\n", args[0])
-	} else {
-		fmt.Print(outs)
-	}
-	return nil
-}
-
-func headBranch() (string, error) {
-	revParseCmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
-	out, err := revParseCmd.Output()
-	if err != nil {
-		return "", commandError(revParseCmd, err)
-	}
-	branch := strings.TrimSpace(string(out))
-	if branch == "master" || strings.HasPrefix(branch, "release") {
-		return branch, fmt.Errorf("branch %s is forbidden", branch)
+		return err
 	}
-	return branch, nil
-}
-
-func remoteBranch(branch string) string {
-	return fmt.Sprintf("%s/%s", remote, branch)
-}
-
-func mergexBranch(branch string) string {
-	return fmt.Sprintf("%s/%s", mergex, branch)
-}
 
-func abortMerge() {
-	mergeCmd := exec.Command("git", "merge", "--abort")
-	_ = mergeCmd.Run()
-}
-
-func deleteBranch(branch string) {
-	branchCmd := exec.Command("git", "branch", "-D", branch)
-	_ = branchCmd.Run()
-}
-
-func commandError(c *exec.Cmd, e error) error {
-	s := c.String()
-	i := strings.Index(s, "git")
-	if i > -1 {
-		s = s[i:]
+	switch {
+	case cmd.abort:
+		return cmd.mergex.Abort(branch)
+	case cmd.cont:
+		return cmd.mergex.Continue(branch, os.Stdin, os.Stdout, os.Stderr)
+	case cmd.remove:
+		return cmd.mergex.Remove()
+	case cmd.dryRun:
+		out, err := cmd.mergex.DryRun(branch, cmd.mergex.Config.Remote, args[0])
+		fmt.Print(out)
+		return err
+	default:
+		out, err := cmd.mergex.Merge(branch, cmd.mergex.Config.Remote, args[0])
+		fmt.Print(out)
+		return err
 	}
-	return fmt.Errorf("%s: %s", s, e)
 }
 
 func boolSum(items ...bool) int {
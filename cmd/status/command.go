@@ -0,0 +1,61 @@
+/*
+Copyright 2021 Shiwen Cheng
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package status implements `git-mergex status`.
+package status
+
+import (
+	"fmt"
+
+	"github.com/chengshiwen/git-mergex/pkg/commands"
+	"github.com/chengshiwen/git-mergex/pkg/worktree"
+	"github.com/spf13/cobra"
+)
+
+// NewCommand builds `git-mergex status`.
+func NewCommand(mergex *commands.Mergex) *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Show the current branch and whether a merge is in progress",
+		Args:  cobra.NoArgs,
+		RunE: func(c *cobra.Command, args []string) error {
+			branch, err := mergex.Status.HeadBranch()
+			if err != nil {
+				return err
+			}
+			fmt.Printf("On branch %s\n", branch)
+			porcelain, err := mergex.Status.Porcelain()
+			if err != nil {
+				return err
+			}
+			if porcelain == "" {
+				fmt.Println("nothing to commit, working tree clean")
+			} else {
+				fmt.Println(porcelain)
+			}
+			entries, err := worktree.List()
+			if err != nil {
+				return err
+			}
+			for _, e := range entries {
+				if e.Branch == branch {
+					fmt.Printf("merge into %s in progress in %s\n", e.TargetRef, e.WorktreePath)
+				}
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2021 Shiwen Cheng
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package worktree
+
+import (
+	"fmt"
+
+	"github.com/chengshiwen/git-mergex/pkg/worktree"
+	"github.com/spf13/cobra"
+)
+
+func NewCommand() *cobra.Command {
+	cobraCmd := &cobra.Command{
+		Use:   "worktree",
+		Short: "Inspect and clean up scratch merge worktrees",
+	}
+	cobraCmd.AddCommand(newListCommand())
+	cobraCmd.AddCommand(newPruneCommand())
+	return cobraCmd
+}
+
+func newListCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List in-progress merge worktrees",
+		Args:  cobra.NoArgs,
+		RunE: func(c *cobra.Command, args []string) error {
+			entries, err := worktree.List()
+			if err != nil {
+				return err
+			}
+			if len(entries) == 0 {
+				fmt.Println("no in-progress merge worktrees")
+				return nil
+			}
+			for _, e := range entries {
+				fmt.Printf("%s\t%s\t-> %s\t(created %s)\n", e.Branch, e.WorktreePath, e.TargetRef, e.CreatedAt.Format("2006-01-02 15:04:05"))
+			}
+			return nil
+		},
+	}
+}
+
+func newPruneCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "prune",
+		Short: "Remove state entries whose worktree no longer exists on disk",
+		Args:  cobra.NoArgs,
+		RunE: func(c *cobra.Command, args []string) error {
+			removed, err := worktree.Prune()
+			if err != nil {
+				return err
+			}
+			for _, branch := range removed {
+				fmt.Printf("pruned %s\n", branch)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2021 Shiwen Cheng
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import "strings"
+
+// Branches lists, creates and removes local and remote branches.
+type Branches struct {
+	*GitCommand
+}
+
+// NewBranches constructs a Branches collaborator.
+func NewBranches(gitCommand *GitCommand) *Branches {
+	return &Branches{GitCommand: gitCommand}
+}
+
+// Remote returns the short names of remote-tracking branches, e.g.
+// "origin/main" trimmed to "main" for remote origin.
+func (b *Branches) Remote(remote string) ([]string, error) {
+	out, err := b.runWithOutput("branch", "-r")
+	if err != nil {
+		return nil, err
+	}
+	prefix := remote + "/"
+	headRef := prefix + "HEAD"
+	seen := make(map[string]bool)
+	var branches []string
+	for _, line := range strings.Split(out, "\n") {
+		branch := strings.TrimSpace(line)
+		if branch == "" || strings.HasPrefix(branch, headRef) {
+			continue
+		}
+		branch = strings.TrimPrefix(branch, prefix)
+		if !seen[branch] {
+			seen[branch] = true
+			branches = append(branches, branch)
+		}
+	}
+	return branches, nil
+}
+
+// Local returns the names of local branches matching prefix.
+func (b *Branches) Local(prefix string) ([]string, error) {
+	out, err := b.runWithOutput("branch")
+	if err != nil {
+		return nil, err
+	}
+	var branches []string
+	for _, line := range strings.Split(out, "\n") {
+		branch := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "* "))
+		if branch != "" && strings.HasPrefix(branch, prefix) {
+			branches = append(branches, branch)
+		}
+	}
+	return branches, nil
+}
+
+// ForceCreate creates or resets branch to point at startPoint.
+func (b *Branches) ForceCreate(branch, startPoint string) error {
+	return b.run("branch", "-f", branch, startPoint)
+}
+
+// Delete force-deletes a single local branch, ignoring the outcome; the
+// caller only ever uses this for best-effort scratch branch cleanup.
+func (b *Branches) Delete(branch string) {
+	_ = b.run("branch", "-D", branch)
+}
+
+// DeleteMany force-deletes several local branches in one call.
+func (b *Branches) DeleteMany(branches []string) error {
+	if len(branches) == 0 {
+		return nil
+	}
+	args := append([]string{"branch", "-D"}, branches...)
+	return b.run(args...)
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2021 Shiwen Cheng
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/chengshiwen/git-mergex/pkg/oscommands"
+)
+
+func TestBranchesRemote(t *testing.T) {
+	runner := oscommands.NewFakeRunner(t, []*oscommands.ExpectedCmd{
+		{Args: []string{"branch", "-r"}, Stdout: "  origin/HEAD -> origin/main\n  origin/main\n  origin/feature/foo\n"},
+	})
+	branches := NewBranches(&GitCommand{Runner: runner})
+
+	got, err := branches.Remote("origin")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"main", "feature/foo"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	runner.CheckDone()
+}
+
+func TestBranchesLocal(t *testing.T) {
+	runner := oscommands.NewFakeRunner(t, []*oscommands.ExpectedCmd{
+		{Args: []string{"branch"}, Stdout: "* main\n  _mergex/feature\n  other\n"},
+	})
+	branches := NewBranches(&GitCommand{Runner: runner})
+
+	got, err := branches.Local("_mergex")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"_mergex/feature"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	runner.CheckDone()
+}
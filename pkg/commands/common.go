@@ -0,0 +1,49 @@
+/*
+Copyright 2021 Shiwen Cheng
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package commands breaks the git-mergex flow down into focused
+// collaborators (Branches, Merges, Status, Mergex) that each run git
+// through an injectable oscommands.Runner, so the flow can be unit tested
+// with a FakeRunner instead of shelling out for real.
+package commands
+
+import (
+	"github.com/chengshiwen/git-mergex/pkg/config"
+	"github.com/chengshiwen/git-mergex/pkg/oscommands"
+)
+
+// GitCommand is the shared handle each collaborator embeds to run git
+// subcommands through an injectable Runner, configured per Config.
+type GitCommand struct {
+	Runner oscommands.Runner
+	Config *config.Config
+}
+
+func (c *GitCommand) run(args ...string) error {
+	return c.Runner.New(args).Run()
+}
+
+func (c *GitCommand) runWithOutput(args ...string) (string, error) {
+	return c.Runner.New(args).RunWithOutput()
+}
+
+func (c *GitCommand) runIn(dir string, args ...string) error {
+	return c.Runner.New(args).SetDir(dir).Run()
+}
+
+func (c *GitCommand) runWithOutputIn(dir string, args ...string) (string, error) {
+	return c.Runner.New(args).SetDir(dir).RunWithOutput()
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2021 Shiwen Cheng
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"io"
+	"strings"
+)
+
+// Merges fetches and drives the merge itself; dir is the worktree it runs
+// each step in.
+type Merges struct {
+	*GitCommand
+}
+
+// NewMerges constructs a Merges collaborator.
+func NewMerges(gitCommand *GitCommand) *Merges {
+	return &Merges{GitCommand: gitCommand}
+}
+
+// Fetch force-fetches ref from remote.
+func (m *Merges) Fetch(remote, ref string) (string, error) {
+	return m.runWithOutput("fetch", "-f", remote, ref)
+}
+
+// DryRun merges ref into dir's checkout without committing, reporting the
+// result, and always aborts the merge attempt afterwards. noFF forces a
+// merge commit even when the merge could be resolved as a fast-forward.
+func (m *Merges) DryRun(dir, ref string, noFF bool) (string, error) {
+	args := append(mergeArgs(noFF), "--no-commit", ref)
+	out, _ := m.runWithOutputIn(dir, args...)
+	_, _ = m.runWithOutputIn(dir, "merge", "--abort")
+	return strings.ReplaceAll(out, "; stopped before committing as requested", ""), nil
+}
+
+// Reset hard-resets dir's checkout to ref.
+func (m *Merges) Reset(dir, ref string) (string, error) {
+	return m.runWithOutputIn(dir, "reset", "--hard", ref)
+}
+
+// Merge merges ref into dir's checkout with the given commit message.
+// noFF forces a merge commit even when the merge could be resolved as a
+// fast-forward.
+func (m *Merges) Merge(dir, ref, message string, noFF bool) (string, error) {
+	args := append(mergeArgs(noFF), "-m", message, ref)
+	return m.runWithOutputIn(dir, args...)
+}
+
+func mergeArgs(noFF bool) []string {
+	if noFF {
+		return []string{"merge", "--no-ff"}
+	}
+	return []string{"merge"}
+}
+
+// Abort cancels a conflicted merge in progress in dir.
+func (m *Merges) Abort(dir string) (string, error) {
+	return m.runWithOutputIn(dir, "merge", "--abort")
+}
+
+// Continue resumes a conflicted merge in dir, wired up to the given
+// streams so the user can resolve prompts (e.g. the default commit
+// message editor) interactively.
+func (m *Merges) Continue(dir string, stdin io.Reader, stdout, stderr io.Writer) error {
+	return m.Runner.New([]string{"merge", "--continue"}).SetDir(dir).RunWithStdio(stdin, stdout, stderr)
+}
+
+// Head returns dir's current commit sha.
+func (m *Merges) Head(dir string) (string, error) {
+	out, err := m.runWithOutputIn(dir, "rev-parse", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// FastForward points branch directly at sha. It never touches the
+// caller's own index or working tree: the caller is responsible for
+// running `git reset --hard` themselves afterwards, the same way a
+// conflicted merge left for Abort never touches it either. Syncing it
+// automatically is not safe in general, since time may have passed (e.g.
+// across a --continue) in which the caller could have committed new
+// work of their own on branch that a blind sync would wipe out.
+func (m *Merges) FastForward(branch, sha string) error {
+	return m.run("update-ref", "refs/heads/"+branch, sha)
+}
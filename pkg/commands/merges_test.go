@@ -0,0 +1,59 @@
+/*
+Copyright 2021 Shiwen Cheng
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"testing"
+
+	"github.com/chengshiwen/git-mergex/pkg/oscommands"
+)
+
+func TestMergesFetch(t *testing.T) {
+	runner := oscommands.NewFakeRunner(t, []*oscommands.ExpectedCmd{
+		{Args: []string{"fetch", "-f", "origin", "feature"}, Stdout: "done"},
+	})
+	merges := NewMerges(&GitCommand{Runner: runner})
+
+	out, err := merges.Fetch("origin", "feature")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out != "done" {
+		t.Errorf("expected %q, got %q", "done", out)
+	}
+	runner.CheckDone()
+}
+
+func TestMergesMergeAndAbort(t *testing.T) {
+	runner := oscommands.NewFakeRunner(t, []*oscommands.ExpectedCmd{
+		{Args: []string{"merge", "--no-ff", "-m", "msg", "_mergex/feature"}, Stdout: "Merge made by the 'recursive' strategy.\n"},
+		{Args: []string{"merge", "--abort"}, Stdout: "aborted"},
+	})
+	merges := NewMerges(&GitCommand{Runner: runner})
+
+	if _, err := merges.Merge("/tmp/wt", "_mergex/feature", "msg", true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out, err := merges.Abort("/tmp/wt")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out != "aborted" {
+		t.Errorf("expected %q, got %q", "aborted", out)
+	}
+	runner.CheckDone()
+}
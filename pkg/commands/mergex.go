@@ -0,0 +1,184 @@
+/*
+Copyright 2021 Shiwen Cheng
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/chengshiwen/git-mergex/pkg/config"
+	"github.com/chengshiwen/git-mergex/pkg/oscommands"
+	"github.com/chengshiwen/git-mergex/pkg/worktree"
+)
+
+// Mergex owns the top-level git-mergex flow, orchestrating Branches,
+// Merges and Status on top of a scratch worktree.
+type Mergex struct {
+	Branches *Branches
+	Merges   *Merges
+	Status   *Status
+	Config   *config.Config
+}
+
+// NewMergex wires a real Runner in for production use; tests construct
+// Mergex from a FakeRunner instead.
+func NewMergex(runner oscommands.Runner, cfg *config.Config) *Mergex {
+	gitCommand := &GitCommand{Runner: runner, Config: cfg}
+	return &Mergex{
+		Branches: NewBranches(gitCommand),
+		Merges:   NewMerges(gitCommand),
+		Status:   NewStatus(gitCommand),
+		Config:   cfg,
+	}
+}
+
+func (m *Mergex) mergexBranch(branch string) string {
+	return fmt.Sprintf("%s/%s", m.Config.MergexPrefix, branch)
+}
+
+// Abort cancels an in-progress merge for the current branch and closes its
+// scratch worktree.
+func (m *Mergex) Abort(branch string) error {
+	runner, err := worktree.Open(branch)
+	if err != nil {
+		return err
+	}
+	if out, err := m.Merges.Abort(runner.Path()); err != nil {
+		fmt.Print(out)
+	}
+	m.Branches.Delete(m.mergexBranch(branch))
+	return runner.Close()
+}
+
+// Continue resumes a conflicted merge for the current branch, interactively
+// wired to stdio, and fast-forwards the caller's branch once it succeeds.
+func (m *Mergex) Continue(branch string, stdin io.Reader, stdout, stderr io.Writer) error {
+	runner, err := worktree.Open(branch)
+	if err != nil {
+		return err
+	}
+	if err := m.Merges.Continue(runner.Path(), stdin, stdout, stderr); err != nil {
+		_ = runner.Touch()
+		return fmt.Errorf("conflicts remain in %s; resolve them there and rerun --continue", runner.Path())
+	}
+	sha, err := m.Merges.Head(runner.Path())
+	if err != nil {
+		return err
+	}
+	if err := m.Merges.FastForward(branch, sha); err != nil {
+		return err
+	}
+	m.Branches.Delete(m.mergexBranch(branch))
+	fmt.Fprintf(stdout, "%s\n", syncHint(branch, sha))
+	return runner.Close()
+}
+
+// Remove force-deletes every local branch under the mergex prefix.
+func (m *Mergex) Remove() error {
+	branches, err := m.Branches.Local(m.Config.MergexPrefix)
+	if err != nil {
+		return err
+	}
+	return m.Branches.DeleteMany(branches)
+}
+
+// DryRun fetches ref from remote and previews merging it into branch
+// without committing or touching the caller's checkout.
+func (m *Mergex) DryRun(branch, remote, ref string) (string, error) {
+	if out, err := m.fetch(remote, ref); err != nil {
+		return out, err
+	}
+	runner, err := worktree.Create(branch, remoteRef(remote, ref))
+	if err != nil {
+		return "", err
+	}
+	out, err := m.Merges.DryRun(runner.Path(), remoteRef(remote, ref), m.Config.NoFF)
+	if err != nil {
+		return out, err
+	}
+	return out, runner.Close()
+}
+
+// Merge fetches ref from remote and merges it into branch inside a scratch
+// worktree, fast-forwarding the caller's branch once the merge completes
+// cleanly. On conflicts the worktree is left in place for --continue or
+// --abort, and the returned error names its path.
+func (m *Mergex) Merge(branch, remote, ref string) (string, error) {
+	if out, err := m.fetch(remote, ref); err != nil {
+		return out, err
+	}
+	target := remoteRef(remote, ref)
+	if err := m.Branches.ForceCreate(m.mergexBranch(branch), branch); err != nil {
+		return "", err
+	}
+	runner, err := worktree.Create(branch, target)
+	if err != nil {
+		return "", err
+	}
+	if out, err := m.Merges.Reset(runner.Path(), target); err != nil {
+		return out, err
+	}
+	message := m.Config.MergeMessage(branch, ref)
+	out, err := m.Merges.Merge(runner.Path(), m.mergexBranch(branch), message, m.Config.NoFF)
+	if err != nil {
+		_ = runner.Touch()
+		return out, fmt.Errorf("merge stopped due to conflicts in %s; resolve them there and rerun --continue or --abort", runner.Path())
+	}
+	sha, err := m.Merges.Head(runner.Path())
+	if err != nil {
+		return "", err
+	}
+	if err := m.Merges.FastForward(branch, sha); err != nil {
+		return "", err
+	}
+	m.Branches.Delete(m.mergexBranch(branch))
+	if strings.Contains(out, "up to date") {
+		out = fmt.Sprintf("Fast-forward to %s\n", ref)
+	}
+	out += syncHint(branch, sha) + "\n"
+	return out, runner.Close()
+}
+
+// syncHint tells the caller how to bring their own checkout back in line
+// with branch now that its ref has moved to sha. FastForward only moves
+// the ref; it deliberately never runs `git reset --hard` against the
+// caller's own working tree itself, since an in-progress merge may have
+// sat in its worktree for a while (e.g. across a --continue) during
+// which the caller could have committed new work of their own on branch
+// that a blind reset would discard. The caller is always already on
+// branch (every command resolves it via Status.HeadBranch), so `git
+// checkout` would be a no-op; `git reset --hard` is the one that syncs it.
+func syncHint(branch, sha string) string {
+	return fmt.Sprintf("%s now points at %s; run `git reset --hard %s` to update your checkout", branch, sha, branch)
+}
+
+func remoteRef(remote, ref string) string {
+	return fmt.Sprintf("%s/%s", remote, ref)
+}
+
+func (m *Mergex) fetch(remote, ref string) (string, error) {
+	out, err := m.Merges.Fetch(remote, ref)
+	if err != nil {
+		hint := ""
+		if strings.Contains(strings.ToLower(out), "couldn't find remote ref") && strings.HasPrefix(ref, remote) {
+			hint = fmt.Sprintf("it seems that the branch '%s' should not start with '%s'\n", ref, remote)
+		}
+		return out + hint, err
+	}
+	return "", nil
+}
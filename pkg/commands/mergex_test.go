@@ -0,0 +1,180 @@
+/*
+Copyright 2021 Shiwen Cheng
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/chengshiwen/git-mergex/pkg/oscommands"
+	"github.com/chengshiwen/git-mergex/pkg/worktree"
+)
+
+// initMergexRepo creates a throwaway git repo with a single commit on
+// branch "feature" and chdirs the test into it. Mergex orchestrates
+// worktree.Create/Close, which shell out to git directly against the
+// process cwd, so the orchestration tests below need a real repo
+// alongside the FakeRunner that stands in for the Branches/Merges/Status
+// collaborators.
+func initMergexRepo(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found in PATH")
+	}
+	dir := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+	run("init", "-b", "feature")
+	run("config", "user.email", "test@example.com")
+	run("config", "user.name", "test")
+	if err := os.WriteFile(dir+"/file.txt", []byte("hello\n"), 0644); err != nil {
+		t.Fatalf("write file.txt: %v", err)
+	}
+	run("add", "file.txt")
+	run("commit", "-m", "initial")
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd: %v", err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = os.Chdir(wd)
+	})
+}
+
+func TestMergexMergeCleanPath(t *testing.T) {
+	initMergexRepo(t)
+
+	runner := oscommands.NewFakeRunner(t, []*oscommands.ExpectedCmd{
+		{Args: []string{"fetch", "-f", "origin", "main"}, Stdout: "done"},
+		{Args: []string{"branch", "-f", "_mergex/feature", "feature"}},
+		{Args: []string{"reset", "--hard", "origin/main"}},
+		{Args: []string{"merge", "--no-ff", "-m", "Merge branch 'feature' into main", "_mergex/feature"}, Stdout: "Merge made by the 'ort' strategy.\n"},
+		{Args: []string{"rev-parse", "HEAD"}, Stdout: "deadbeef\n"},
+		{Args: []string{"update-ref", "refs/heads/feature", "deadbeef"}},
+		{Args: []string{"branch", "-D", "_mergex/feature"}},
+	})
+	mergex := NewMergex(runner, testConfig())
+
+	out, err := mergex.Merge("feature", "origin", "main")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(out, "ort") {
+		t.Errorf("expected merge output to be returned, got %q", out)
+	}
+	if !strings.Contains(out, "git reset --hard feature") {
+		t.Errorf("expected a hint to sync the caller's own checkout, got %q", out)
+	}
+	runner.CheckDone()
+
+	entries, listErr := worktree.List()
+	if listErr != nil {
+		t.Fatalf("List: %v", listErr)
+	}
+	if len(entries) != 0 {
+		t.Errorf("expected the worktree to be closed after a clean merge, got %v", entries)
+	}
+}
+
+func TestMergexMergeConflictPath(t *testing.T) {
+	initMergexRepo(t)
+
+	runner := oscommands.NewFakeRunner(t, []*oscommands.ExpectedCmd{
+		{Args: []string{"fetch", "-f", "origin", "main"}, Stdout: "done"},
+		{Args: []string{"branch", "-f", "_mergex/feature", "feature"}},
+		{Args: []string{"reset", "--hard", "origin/main"}},
+		{Args: []string{"merge", "--no-ff", "-m", "Merge branch 'feature' into main", "_mergex/feature"}, Stdout: "CONFLICT (content): Merge conflict in file.txt\n", Err: errors.New("exit status 1")},
+	})
+	mergex := NewMergex(runner, testConfig())
+
+	_, err := mergex.Merge("feature", "origin", "main")
+	if err == nil {
+		t.Fatal("expected an error on conflict")
+	}
+	if !strings.Contains(err.Error(), "rerun --continue or --abort") {
+		t.Errorf("expected a --continue/--abort hint, got %q", err)
+	}
+	runner.CheckDone()
+
+	entries, listErr := worktree.List()
+	if listErr != nil {
+		t.Fatalf("List: %v", listErr)
+	}
+	if len(entries) != 1 || entries[0].Branch != "feature" {
+		t.Fatalf("expected the worktree to be left in place for --continue/--abort, got %v", entries)
+	}
+
+	// clean up the worktree this test left behind for the next merge.
+	opened, err := worktree.Open("feature")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if err := opened.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+}
+
+func TestMergexContinueSyncsRefButNotCheckout(t *testing.T) {
+	initMergexRepo(t)
+	// Simulate a conflicted merge that already left a worktree behind,
+	// the way Mergex.Merge does on conflict.
+	if _, err := worktree.Create("feature", "origin/main"); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	runner := oscommands.NewFakeRunner(t, []*oscommands.ExpectedCmd{
+		{Args: []string{"merge", "--continue"}},
+		{Args: []string{"rev-parse", "HEAD"}, Stdout: "cafebabe\n"},
+		{Args: []string{"update-ref", "refs/heads/feature", "cafebabe"}},
+		{Args: []string{"branch", "-D", "_mergex/feature"}},
+	})
+	mergex := NewMergex(runner, testConfig())
+
+	var stdout strings.Builder
+	if err := mergex.Continue("feature", strings.NewReader(""), &stdout, &stdout); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// FastForward only moved the branch ref (recorded above); Continue
+	// must never issue a "reset --hard" of its own against the caller's
+	// checkout, since the caller may have kept committing on branch
+	// while the conflict sat unresolved in the worktree.
+	runner.CheckDone()
+	if !strings.Contains(stdout.String(), "git reset --hard feature") {
+		t.Errorf("expected a hint to sync the caller's own checkout, got %q", stdout.String())
+	}
+
+	entries, err := worktree.List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("expected the worktree to be closed after continuing, got %v", entries)
+	}
+}
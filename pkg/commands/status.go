@@ -0,0 +1,57 @@
+/*
+Copyright 2021 Shiwen Cheng
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Status inspects the current branch and working tree.
+type Status struct {
+	*GitCommand
+}
+
+// NewStatus constructs a Status collaborator.
+func NewStatus(gitCommand *GitCommand) *Status {
+	return &Status{GitCommand: gitCommand}
+}
+
+// HeadBranch returns the branch HEAD is on, erroring out if it matches
+// Config.ForbiddenBranches, the branches git-mergex refuses to run on
+// (by default master and any release* branch).
+func (s *Status) HeadBranch() (string, error) {
+	out, err := s.runWithOutput("rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	branch := strings.TrimSpace(out)
+	if s.Config.Forbidden(branch) {
+		return branch, fmt.Errorf("branch %s is forbidden", branch)
+	}
+	return branch, nil
+}
+
+// Porcelain returns the trimmed `git status --porcelain -uno` output,
+// empty when the working tree (excluding untracked files) is clean.
+func (s *Status) Porcelain() (string, error) {
+	out, err := s.runWithOutput("status", "--porcelain", "-uno")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
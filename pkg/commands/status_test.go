@@ -0,0 +1,64 @@
+/*
+Copyright 2021 Shiwen Cheng
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package commands
+
+import (
+	"testing"
+
+	"github.com/chengshiwen/git-mergex/pkg/config"
+	"github.com/chengshiwen/git-mergex/pkg/oscommands"
+)
+
+func testConfig() *config.Config {
+	return &config.Config{
+		Remote:               config.DefaultRemote,
+		MergexPrefix:         config.DefaultMergexPrefix,
+		ForbiddenBranches:    config.DefaultForbiddenBranches,
+		MergeMessageTemplate: config.DefaultMergeMessageTemplate,
+		NoFF:                 true,
+	}
+}
+
+func TestStatusHeadBranch(t *testing.T) {
+	runner := oscommands.NewFakeRunner(t, []*oscommands.ExpectedCmd{
+		{Args: []string{"rev-parse", "--abbrev-ref", "HEAD"}, Stdout: "feature\n"},
+	})
+	status := NewStatus(&GitCommand{Runner: runner, Config: testConfig()})
+
+	branch, err := status.HeadBranch()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if branch != "feature" {
+		t.Errorf("expected %q, got %q", "feature", branch)
+	}
+	runner.CheckDone()
+}
+
+func TestStatusHeadBranchForbidden(t *testing.T) {
+	for _, branch := range []string{"master", "release-1.0"} {
+		runner := oscommands.NewFakeRunner(t, []*oscommands.ExpectedCmd{
+			{Args: []string{"rev-parse", "--abbrev-ref", "HEAD"}, Stdout: branch},
+		})
+		status := NewStatus(&GitCommand{Runner: runner, Config: testConfig()})
+
+		if _, err := status.HeadBranch(); err == nil {
+			t.Errorf("expected branch %q to be forbidden", branch)
+		}
+		runner.CheckDone()
+	}
+}
@@ -0,0 +1,200 @@
+/*
+Copyright 2021 Shiwen Cheng
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package config lets a team adopt git-mergex without forking it: the
+// remote name, the scratch-branch prefix, which checked-out branches
+// git-mergex refuses to run on, the merge commit message and whether merges are forced
+// non-fast-forward can all be customized via .git/mergex.yaml and/or
+// `git config mergex.*`, with the latter taking precedence. Anything left
+// unset falls back to git-mergex's historical defaults.
+package config
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/chengshiwen/git-mergex/pkg/oscommands"
+)
+
+const (
+	// DefaultRemote is the remote git-mergex fetches and merges from.
+	DefaultRemote = "origin"
+	// DefaultMergexPrefix namespaces the scratch branches git-mergex
+	// creates to stand in for the caller's branch during a merge.
+	DefaultMergexPrefix = "_mergex"
+	// DefaultMergeMessageTemplate is the merge commit message, with
+	// {{.Branch}} and {{.Ref}} substituted in.
+	DefaultMergeMessageTemplate = "Merge branch '{{.Branch}}' into {{.Ref}}"
+
+	yamlPath = ".git/mergex.yaml"
+)
+
+// DefaultForbiddenBranches lists the branches git-mergex refuses to run on
+// (as the checked-out branch) by default; a trailing '*' matches as a prefix.
+var DefaultForbiddenBranches = []string{"master", "release*"}
+
+// Config holds the team-customizable parts of the git-mergex flow.
+type Config struct {
+	Remote               string
+	MergexPrefix         string
+	ForbiddenBranches    []string
+	MergeMessageTemplate string
+	NoFF                 bool
+}
+
+// Load reads .git/mergex.yaml (if present), then overlays any `git config
+// mergex.*` values (if set), falling back to built-in defaults for
+// anything still unset.
+func Load(runner oscommands.Runner) (*Config, error) {
+	cfg := &Config{
+		Remote:               DefaultRemote,
+		MergexPrefix:         DefaultMergexPrefix,
+		ForbiddenBranches:    append([]string(nil), DefaultForbiddenBranches...),
+		MergeMessageTemplate: DefaultMergeMessageTemplate,
+		NoFF:                 true,
+	}
+	if err := cfg.loadYAML(yamlPath); err != nil {
+		return nil, err
+	}
+	cfg.loadGitConfig(runner)
+	return cfg, nil
+}
+
+// Forbidden reports whether branch matches one of the forbidden patterns.
+func (c *Config) Forbidden(branch string) bool {
+	for _, pattern := range c.ForbiddenBranches {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(branch, prefix) {
+				return true
+			}
+		} else if branch == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// MergeMessage renders the configured merge message template for merging
+// ref into branch.
+func (c *Config) MergeMessage(branch, ref string) string {
+	replacer := strings.NewReplacer("{{.Branch}}", branch, "{{.Ref}}", ref)
+	return replacer.Replace(c.MergeMessageTemplate)
+}
+
+func (c *Config) loadYAML(path string) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	lines := strings.Split(string(data), "\n")
+	for i := 0; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(trimmed, ":")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		if value != "" {
+			c.setScalar(key, strings.Trim(value, `"'`))
+			continue
+		}
+		// A key with no inline value is a YAML list, one item per
+		// following indented "- item" line.
+		var items []string
+		for i+1 < len(lines) {
+			next := strings.TrimSpace(lines[i+1])
+			if !strings.HasPrefix(next, "- ") {
+				break
+			}
+			items = append(items, strings.TrimSpace(strings.TrimPrefix(next, "-")))
+			i++
+		}
+		c.setList(key, items)
+	}
+	return nil
+}
+
+func (c *Config) setScalar(key, value string) {
+	switch key {
+	case "remote":
+		c.Remote = value
+	case "mergexPrefix":
+		c.MergexPrefix = value
+	case "mergeMessageTemplate":
+		c.MergeMessageTemplate = value
+	case "noFF":
+		if b, err := strconv.ParseBool(value); err == nil {
+			c.NoFF = b
+		}
+	}
+}
+
+func (c *Config) setList(key string, items []string) {
+	if key == "forbiddenBranches" && len(items) > 0 {
+		c.ForbiddenBranches = items
+	}
+}
+
+func (c *Config) loadGitConfig(runner oscommands.Runner) {
+	if v, ok := gitConfigGet(runner, "mergex.remote"); ok {
+		c.Remote = v
+	}
+	if v, ok := gitConfigGet(runner, "mergex.mergexprefix"); ok {
+		c.MergexPrefix = v
+	}
+	if v, ok := gitConfigGet(runner, "mergex.mergemessagetemplate"); ok {
+		c.MergeMessageTemplate = v
+	}
+	if v, ok := gitConfigGet(runner, "mergex.noff"); ok {
+		if b, err := strconv.ParseBool(v); err == nil {
+			c.NoFF = b
+		}
+	}
+	if items, ok := gitConfigGetAll(runner, "mergex.forbiddenbranches"); ok && len(items) > 0 {
+		c.ForbiddenBranches = items
+	}
+}
+
+func gitConfigGet(runner oscommands.Runner, key string) (string, bool) {
+	out, err := runner.New([]string{"config", "--get", key}).RunWithOutput()
+	if err != nil {
+		return "", false
+	}
+	return strings.TrimSpace(out), true
+}
+
+func gitConfigGetAll(runner oscommands.Runner, key string) ([]string, bool) {
+	out, err := runner.New([]string{"config", "--get-all", key}).RunWithOutput()
+	if err != nil {
+		return nil, false
+	}
+	var items []string
+	for _, line := range strings.Split(out, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			items = append(items, line)
+		}
+	}
+	return items, len(items) > 0
+}
@@ -0,0 +1,46 @@
+/*
+Copyright 2021 Shiwen Cheng
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import "testing"
+
+func TestConfigForbidden(t *testing.T) {
+	cfg := &Config{ForbiddenBranches: []string{"master", "release*"}}
+
+	cases := map[string]bool{
+		"master":      true,
+		"release-1.0": true,
+		"releaser":    true,
+		"main":        false,
+		"feature/foo": false,
+	}
+	for branch, want := range cases {
+		if got := cfg.Forbidden(branch); got != want {
+			t.Errorf("Forbidden(%q) = %v, want %v", branch, got, want)
+		}
+	}
+}
+
+func TestConfigMergeMessage(t *testing.T) {
+	cfg := &Config{MergeMessageTemplate: DefaultMergeMessageTemplate}
+
+	got := cfg.MergeMessage("feature", "main")
+	want := "Merge branch 'feature' into main"
+	if got != want {
+		t.Errorf("MergeMessage() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2021 Shiwen Cheng
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package oscommands wraps shell-out execution behind a small interface so
+// the rest of the codebase can run real commands in production and canned
+// commands in tests, instead of calling exec.Command directly.
+package oscommands
+
+import (
+	"io"
+	"os/exec"
+)
+
+// CmdObj represents a single external command, built but not yet run.
+type CmdObj interface {
+	// Args returns the argv this command was constructed with.
+	Args() []string
+	// SetDir sets the working directory the command runs in and returns
+	// the receiver, so construction can be chained.
+	SetDir(dir string) CmdObj
+	// Run executes the command, discarding its output.
+	Run() error
+	// RunWithOutput executes the command and returns its combined
+	// stdout+stderr.
+	RunWithOutput() (string, error)
+	// RunWithStdio executes the command wired up to the given streams,
+	// e.g. to let a conflicted `git merge --continue` prompt interactively.
+	RunWithStdio(stdin io.Reader, stdout, stderr io.Writer) error
+}
+
+type execCmdObj struct {
+	args []string
+	cmd  *exec.Cmd
+}
+
+func newCmdObj(args []string) *execCmdObj {
+	return &execCmdObj{args: args, cmd: exec.Command("git", args...)}
+}
+
+func (c *execCmdObj) Args() []string {
+	return c.args
+}
+
+func (c *execCmdObj) SetDir(dir string) CmdObj {
+	c.cmd.Dir = dir
+	return c
+}
+
+func (c *execCmdObj) Run() error {
+	return c.cmd.Run()
+}
+
+func (c *execCmdObj) RunWithOutput() (string, error) {
+	out, err := c.cmd.CombinedOutput()
+	return string(out), err
+}
+
+func (c *execCmdObj) RunWithStdio(stdin io.Reader, stdout, stderr io.Writer) error {
+	c.cmd.Stdin = stdin
+	c.cmd.Stdout = stdout
+	c.cmd.Stderr = stderr
+	return c.cmd.Run()
+}
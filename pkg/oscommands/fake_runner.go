@@ -0,0 +1,98 @@
+/*
+Copyright 2021 Shiwen Cheng
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oscommands
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+)
+
+// ExpectedCmd describes one command a test expects to be run, in order,
+// and what it should appear to return.
+type ExpectedCmd struct {
+	Args   []string
+	Stdout string
+	Err    error
+}
+
+// FakeRunner asserts that commands are built in a fixed, expected order
+// and hands back the canned output configured for each one.
+type FakeRunner struct {
+	t        *testing.T
+	expected []*ExpectedCmd
+	index    int
+}
+
+// NewFakeRunner builds a FakeRunner that expects exactly the commands in
+// expected, in order.
+func NewFakeRunner(t *testing.T, expected []*ExpectedCmd) *FakeRunner {
+	return &FakeRunner{t: t, expected: expected}
+}
+
+func (r *FakeRunner) New(args []string) CmdObj {
+	r.t.Helper()
+	if r.index >= len(r.expected) {
+		r.t.Fatalf("unexpected command run: %v", args)
+	}
+	exp := r.expected[r.index]
+	r.index++
+	if !reflect.DeepEqual(exp.Args, args) {
+		r.t.Errorf("expected command %v, got %v", exp.Args, args)
+	}
+	return &fakeCmdObj{args: args, stdout: exp.Stdout, err: exp.Err}
+}
+
+// CheckDone fails the test if any expected command was never run.
+func (r *FakeRunner) CheckDone() {
+	r.t.Helper()
+	if r.index != len(r.expected) {
+		r.t.Errorf("expected %d commands to run, only %d did", len(r.expected), r.index)
+	}
+}
+
+type fakeCmdObj struct {
+	args   []string
+	dir    string
+	stdout string
+	err    error
+}
+
+func (c *fakeCmdObj) Args() []string {
+	return c.args
+}
+
+func (c *fakeCmdObj) SetDir(dir string) CmdObj {
+	c.dir = dir
+	return c
+}
+
+func (c *fakeCmdObj) Run() error {
+	return c.err
+}
+
+func (c *fakeCmdObj) RunWithOutput() (string, error) {
+	return c.stdout, c.err
+}
+
+func (c *fakeCmdObj) RunWithStdio(stdin io.Reader, stdout, stderr io.Writer) error {
+	if c.stdout != "" {
+		_, _ = fmt.Fprint(stdout, c.stdout)
+	}
+	return c.err
+}
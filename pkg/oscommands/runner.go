@@ -0,0 +1,36 @@
+/*
+Copyright 2021 Shiwen Cheng
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oscommands
+
+// Runner builds CmdObjs. Production code wires in OSRunner; tests wire in
+// a FakeRunner that matches expected argv patterns and returns canned
+// output instead of touching the filesystem.
+type Runner interface {
+	New(args []string) CmdObj
+}
+
+// OSRunner is the real Runner, backed by os/exec.
+type OSRunner struct{}
+
+// NewOSRunner returns a Runner that shells out for real.
+func NewOSRunner() *OSRunner {
+	return &OSRunner{}
+}
+
+func (r *OSRunner) New(args []string) CmdObj {
+	return newCmdObj(args)
+}
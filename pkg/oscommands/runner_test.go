@@ -0,0 +1,47 @@
+/*
+Copyright 2021 Shiwen Cheng
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package oscommands
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// TestOSRunnerRunsGit exercises the real OSRunner against an actual git
+// binary, so a regression in how the argv is built (e.g. forgetting to
+// exec "git" itself) fails here instead of only ever being caught by
+// FakeRunner-based tests that assert the same argv they were given.
+func TestOSRunnerRunsGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found in PATH")
+	}
+	dir := t.TempDir()
+	runner := NewOSRunner()
+
+	if err := runner.New([]string{"init"}).SetDir(dir).Run(); err != nil {
+		t.Fatalf("git init: %v", err)
+	}
+
+	out, err := runner.New([]string{"rev-parse", "--is-inside-work-tree"}).SetDir(dir).RunWithOutput()
+	if err != nil {
+		t.Fatalf("rev-parse: %v (out: %s)", err, out)
+	}
+	if got := strings.TrimSpace(out); got != "true" {
+		t.Errorf("expected %q, got %q", "true", got)
+	}
+}
@@ -0,0 +1,257 @@
+/*
+Copyright 2021 Shiwen Cheng
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package worktree isolates a merge inside a scratch git worktree so that
+// the caller's checkout is never touched by `git reset --hard`. A merge
+// runs entirely inside the worktree; only once it completes cleanly is the
+// caller's branch fast-forwarded to the resulting commit.
+package worktree
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Entry records the bookkeeping for a single branch's in-progress merge.
+type Entry struct {
+	Branch       string    `json:"branch"`
+	WorktreePath string    `json:"worktreePath"`
+	TargetRef    string    `json:"targetRef"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+type stateMap map[string]Entry
+
+// statePath resolves the state file against the repo's real git-dir
+// (rather than a path relative to the process cwd), so it lands at
+// <git-dir>/mergex/state.json regardless of which subdirectory of the
+// repo git-mergex is run from.
+func statePath() (string, error) {
+	dir, err := gitDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "mergex", "state.json"), nil
+}
+
+func loadState() (stateMap, error) {
+	path, err := statePath()
+	if err != nil {
+		return nil, err
+	}
+	s := make(stateMap)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return s, nil
+	}
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func saveState(s stateMap) error {
+	path, err := statePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// List returns every tracked entry, sorted by branch name.
+func List() ([]Entry, error) {
+	s, err := loadState()
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]Entry, 0, len(s))
+	for _, e := range s {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Branch < entries[j].Branch
+	})
+	return entries, nil
+}
+
+// Prune removes state entries whose worktree directory is gone and tells
+// git to forget any worktree administrative files left behind.
+func Prune() ([]string, error) {
+	s, err := loadState()
+	if err != nil {
+		return nil, err
+	}
+	var removed []string
+	for branch, e := range s {
+		if _, err := os.Stat(e.WorktreePath); os.IsNotExist(err) {
+			delete(s, branch)
+			removed = append(removed, branch)
+		}
+	}
+	if err := saveState(s); err != nil {
+		return nil, err
+	}
+	pruneCmd := exec.Command("git", "worktree", "prune")
+	if out, err := pruneCmd.CombinedOutput(); err != nil {
+		return removed, fmt.Errorf("git worktree prune: %s: %w", string(out), err)
+	}
+	return removed, nil
+}
+
+// Runner owns a scratch worktree rooted at worktreePath, checked out from
+// the caller's repo at originalGitPath, that a merge is performed inside.
+type Runner struct {
+	originalGitPath string
+	worktreePath    string
+	branch          string
+	targetRef       string
+}
+
+// Create checks out branch into a fresh worktree under
+// <git-dir>/mergex-worktrees and records it in the state file.
+func Create(branch, targetRef string) (*Runner, error) {
+	gitDir, err := gitDir()
+	if err != nil {
+		return nil, err
+	}
+	worktreePath := filepath.Join(gitDir, "mergex-worktrees", fmt.Sprintf("%s-%d", sanitize(branch), os.Getpid()))
+	addCmd := exec.Command("git", "worktree", "add", "--detach", worktreePath, branch)
+	if out, err := addCmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("git worktree add: %s: %w", string(out), err)
+	}
+
+	now := time.Now()
+	if err := upsertEntry(Entry{
+		Branch:       branch,
+		WorktreePath: worktreePath,
+		TargetRef:    targetRef,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}); err != nil {
+		return nil, err
+	}
+	return &Runner{originalGitPath: gitDir, worktreePath: worktreePath, branch: branch, targetRef: targetRef}, nil
+}
+
+// Open loads the worktree previously recorded for branch, e.g. to resume
+// it from --continue or --abort.
+func Open(branch string) (*Runner, error) {
+	s, err := loadState()
+	if err != nil {
+		return nil, err
+	}
+	e, ok := s[branch]
+	if !ok {
+		return nil, fmt.Errorf("no in-progress merge worktree found for branch %s", branch)
+	}
+	gitDir, err := gitDir()
+	if err != nil {
+		return nil, err
+	}
+	return &Runner{originalGitPath: gitDir, worktreePath: e.WorktreePath, branch: branch, targetRef: e.TargetRef}, nil
+}
+
+func upsertEntry(e Entry) error {
+	s, err := loadState()
+	if err != nil {
+		return err
+	}
+	s[e.Branch] = e
+	return saveState(s)
+}
+
+func removeEntry(branch string) error {
+	s, err := loadState()
+	if err != nil {
+		return err
+	}
+	delete(s, branch)
+	return saveState(s)
+}
+
+// Path returns the filesystem path of the scratch worktree, for the
+// caller to run the actual merge commands in.
+func (r *Runner) Path() string {
+	return r.worktreePath
+}
+
+// Branch returns the caller's branch this worktree was created for.
+func (r *Runner) Branch() string {
+	return r.branch
+}
+
+// Close removes the worktree, forgets it in the state file, and prunes
+// git's worktree administrative files. It is safe to call after both a
+// successful merge and an abort.
+func (r *Runner) Close() error {
+	removeCmd := exec.Command("git", "worktree", "remove", "--force", r.worktreePath)
+	if out, err := removeCmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git worktree remove: %s: %w", string(out), err)
+	}
+	if err := removeEntry(r.branch); err != nil {
+		return err
+	}
+	pruneCmd := exec.Command("git", "worktree", "prune")
+	return pruneCmd.Run()
+}
+
+// Touch refreshes the UpdatedAt timestamp of the tracked entry, e.g. after
+// a conflicted merge is left in place for the user to resolve.
+func (r *Runner) Touch() error {
+	s, err := loadState()
+	if err != nil {
+		return err
+	}
+	e, ok := s[r.branch]
+	if !ok {
+		e = Entry{Branch: r.branch, WorktreePath: r.worktreePath, TargetRef: r.targetRef, CreatedAt: time.Now()}
+	}
+	e.UpdatedAt = time.Now()
+	return upsertEntry(e)
+}
+
+func gitDir() (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--git-dir")
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git rev-parse --git-dir: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func sanitize(branch string) string {
+	return strings.ReplaceAll(branch, "/", "-")
+}
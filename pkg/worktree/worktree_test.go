@@ -0,0 +1,144 @@
+/*
+Copyright 2021 Shiwen Cheng
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package worktree
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// initRepo creates a throwaway git repo with a single commit on branch
+// "feature", chdirs the test into it, and restores the original working
+// directory on cleanup. Create/Open/Close shell out to git directly
+// against the process cwd, so exercising them for real needs an actual
+// repo rather than a FakeRunner.
+func initRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found in PATH")
+	}
+	dir := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+	run("init", "-b", "feature")
+	run("config", "user.email", "test@example.com")
+	run("config", "user.name", "test")
+	if err := os.WriteFile(filepath.Join(dir, "file.txt"), []byte("hello\n"), 0644); err != nil {
+		t.Fatalf("write file.txt: %v", err)
+	}
+	run("add", "file.txt")
+	run("commit", "-m", "initial")
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd: %v", err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = os.Chdir(wd)
+	})
+	return dir
+}
+
+func TestCreateOpenClose(t *testing.T) {
+	initRepo(t)
+
+	runner, err := Create("feature", "feature")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err := os.Stat(runner.Path()); err != nil {
+		t.Errorf("expected worktree dir to exist: %v", err)
+	}
+
+	entries, err := List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Branch != "feature" {
+		t.Fatalf("expected one tracked entry for feature, got %v", entries)
+	}
+
+	opened, err := Open("feature")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if opened.Path() != runner.Path() {
+		t.Errorf("Open returned path %q, want %q", opened.Path(), runner.Path())
+	}
+	if opened.Branch() != "feature" {
+		t.Errorf("Open returned branch %q, want %q", opened.Branch(), "feature")
+	}
+
+	if err := runner.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if _, err := os.Stat(runner.Path()); !os.IsNotExist(err) {
+		t.Errorf("expected worktree dir to be gone after Close, got err=%v", err)
+	}
+	entries, err = List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("expected no tracked entries after Close, got %v", entries)
+	}
+}
+
+func TestOpenUnknownBranch(t *testing.T) {
+	initRepo(t)
+
+	if _, err := Open("does-not-exist"); err == nil {
+		t.Error("expected an error opening a branch with no recorded worktree")
+	}
+}
+
+func TestTouchUpdatesEntry(t *testing.T) {
+	initRepo(t)
+
+	runner, err := Create("feature", "feature")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	defer runner.Close()
+
+	before, err := List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+
+	if err := runner.Touch(); err != nil {
+		t.Fatalf("Touch: %v", err)
+	}
+	after, err := List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if !after[0].UpdatedAt.After(before[0].UpdatedAt) && !after[0].UpdatedAt.Equal(before[0].UpdatedAt) {
+		t.Errorf("expected UpdatedAt not to go backwards, before=%v after=%v", before[0].UpdatedAt, after[0].UpdatedAt)
+	}
+}